@@ -0,0 +1,285 @@
+package challonge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetScore is one game's score within a (possibly best-of-N) match.
+type SetScore struct {
+	PlayerOne int
+	PlayerTwo int
+}
+
+// parseScoresCSV parses Challonge's comma-separated "scores_csv" field
+// (e.g. "3-2,0-3,4-1") into one SetScore per game.
+func parseScoresCSV(csv string) ([]SetScore, error) {
+	csv = strings.TrimSpace(csv)
+	if csv == "" {
+		return nil, nil
+	}
+
+	sets := make([]SetScore, 0)
+	for _, set := range strings.Split(csv, ",") {
+		set = strings.TrimSpace(set)
+		if set == "" {
+			continue
+		}
+		a, b, err := separateScores(set)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, SetScore{PlayerOne: a, PlayerTwo: b})
+	}
+	return sets, nil
+}
+
+// setScoresCSV is the inverse of parseScoresCSV, producing the comma
+// separated form Challonge expects in match[scores_csv].
+func setScoresCSV(sets []SetScore) string {
+	if len(sets) == 0 {
+		return ""
+	}
+	parts := make([]string, len(sets))
+	for i, s := range sets {
+		parts[i] = fmt.Sprintf("%d-%d", s.PlayerOne, s.PlayerTwo)
+	}
+	return strings.Join(parts, ",")
+}
+
+// MatchAttachment is a file, URL, or note attached to a match, e.g. a
+// screenshot backing up a reported score.
+type MatchAttachment struct {
+	ID               int       `json:"id"`
+	URL              string    `json:"url"`
+	Description      string    `json:"description"`
+	OriginalFileName string    `json:"original_file_name"`
+	AssetURL         string    `json:"asset_url"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type matchAttachmentItem struct {
+	MatchAttachment MatchAttachment `json:"match_attachment"`
+}
+
+func (m *Match) attachmentsRoute(t *Tournament, suffix string) string {
+	route := fmt.Sprintf("tournaments/%s/matches/%d/attachments", t.GetUrl(), m.ID)
+	if suffix != "" {
+		route += "/" + suffix
+	}
+	return route
+}
+
+// ListAttachments returns every attachment on m.
+func (m *Match) ListAttachments(ctx context.Context) ([]*MatchAttachment, error) {
+	t, err := m.requireTournament()
+	if err != nil {
+		return nil, err
+	}
+	c := t.clientOrDefault()
+	reqURL := c.buildUrl(m.attachmentsRoute(t, ""), nil)
+	var items []*matchAttachmentItem
+	if err := c.doGet(ctx, reqURL, &items); err != nil {
+		return nil, fmt.Errorf("unable to list match attachments: %w", err)
+	}
+	attachments := make([]*MatchAttachment, 0, len(items))
+	for _, item := range items {
+		a := item.MatchAttachment
+		attachments = append(attachments, &a)
+	}
+	return attachments, nil
+}
+
+// AddAttachment creates a new attachment on m. When file is non-nil its
+// contents are uploaded as a multipart asset; otherwise a carries only a
+// URL and/or description.
+func (m *Match) AddAttachment(ctx context.Context, a *MatchAttachment, file io.Reader) (*MatchAttachment, error) {
+	t, err := m.requireTournament()
+	if err != nil {
+		return nil, err
+	}
+	c := t.clientOrDefault()
+	response := &matchAttachmentItem{}
+
+	if file != nil {
+		fields := map[string]string{}
+		if a.Description != "" {
+			fields["match_attachment[description]"] = a.Description
+		}
+		fileName := a.OriginalFileName
+		if fileName == "" {
+			fileName = "attachment"
+		}
+		reqURL := c.buildUrl(m.attachmentsRoute(t, ""), nil)
+		if err := c.doMultipart(ctx, http.MethodPost, reqURL, fields, "match_attachment[asset]", fileName, file, response); err != nil {
+			return nil, fmt.Errorf("unable to upload match attachment: %w", err)
+		}
+		return &response.MatchAttachment, nil
+	}
+
+	v := url.Values{}
+	if a.URL != "" {
+		v.Set("match_attachment[url]", a.URL)
+	}
+	if a.Description != "" {
+		v.Set("match_attachment[description]", a.Description)
+	}
+	reqURL := c.buildUrl(m.attachmentsRoute(t, ""), v)
+	if err := c.doPost(ctx, reqURL, response); err != nil {
+		return nil, fmt.Errorf("unable to add match attachment: %w", err)
+	}
+	return &response.MatchAttachment, nil
+}
+
+// UpdateAttachment edits the url/description of an existing attachment.
+func (m *Match) UpdateAttachment(ctx context.Context, id int, a *MatchAttachment) (*MatchAttachment, error) {
+	t, err := m.requireTournament()
+	if err != nil {
+		return nil, err
+	}
+	c := t.clientOrDefault()
+	v := url.Values{}
+	if a.URL != "" {
+		v.Set("match_attachment[url]", a.URL)
+	}
+	if a.Description != "" {
+		v.Set("match_attachment[description]", a.Description)
+	}
+	reqURL := c.buildUrl(m.attachmentsRoute(t, strconv.Itoa(id)), v)
+	response := &matchAttachmentItem{}
+	if err := c.doPut(ctx, reqURL, response); err != nil {
+		return nil, fmt.Errorf("unable to update match attachment: %w", err)
+	}
+	return &response.MatchAttachment, nil
+}
+
+// DeleteAttachment removes an attachment from m.
+func (m *Match) DeleteAttachment(ctx context.Context, id int) error {
+	t, err := m.requireTournament()
+	if err != nil {
+		return err
+	}
+	c := t.clientOrDefault()
+	reqURL := c.buildUrl(m.attachmentsRoute(t, strconv.Itoa(id)), nil)
+	response := &matchAttachmentItem{}
+	if err := c.doDelete(ctx, reqURL, response); err != nil {
+		return fmt.Errorf("unable to delete match attachment: %w", err)
+	}
+	return nil
+}
+
+// transition POSTs to one of the match state-change endpoints and replaces
+// m's fields with the result.
+func (m *Match) transition(ctx context.Context, action string) error {
+	t, err := m.requireTournament()
+	if err != nil {
+		return err
+	}
+	c := t.clientOrDefault()
+	route := fmt.Sprintf("tournaments/%s/matches/%d/%s", t.GetUrl(), m.ID, action)
+	response := &APIResponse{}
+	if err := c.doPost(ctx, c.buildUrl(route, nil), response); err != nil {
+		return fmt.Errorf("unable to %s match: %w", action, err)
+	}
+	*m = response.Match
+	m.tournament = t
+	return nil
+}
+
+// Reopen reverts a completed match back to open, undoing its score report.
+func (m *Match) Reopen(ctx context.Context) error {
+	return m.transition(ctx, "reopen")
+}
+
+// MarkAsUnderway flags m as currently being played.
+func (m *Match) MarkAsUnderway(ctx context.Context) error {
+	return m.transition(ctx, "mark_as_underway")
+}
+
+// UnmarkAsUnderway clears the underway flag set by MarkAsUnderway.
+func (m *Match) UnmarkAsUnderway(ctx context.Context) error {
+	return m.transition(ctx, "unmark_as_underway")
+}
+
+// MatchEvent is one state transition in a tournament's match history, e.g.
+// a match moving from "open" to "complete".
+type MatchEvent struct {
+	MatchID   int
+	State     string
+	Timestamp time.Time
+}
+
+// ListMatchEvents fetches the tournament's current matches and returns the
+// ones that changed state after since, ordered by Timestamp. Callers can
+// keep the returned events' latest Timestamp as the since for the next
+// poll.
+func (t *Tournament) ListMatchEvents(ctx context.Context, since time.Time) ([]*MatchEvent, error) {
+	c := t.clientOrDefault()
+	reqURL := c.buildUrl("tournaments/"+t.GetUrl()+"/matches", nil)
+	var items []*MatchItem
+	if err := c.doGet(ctx, reqURL, &items); err != nil {
+		return nil, fmt.Errorf("unable to list match events: %w", err)
+	}
+
+	events := make([]*MatchEvent, 0)
+	for _, item := range items {
+		if !item.Match.UpdatedAt.After(since) {
+			continue
+		}
+		events = append(events, &MatchEvent{
+			MatchID:   item.Match.ID,
+			State:     item.Match.State,
+			Timestamp: item.Match.UpdatedAt,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	return events, nil
+}
+
+func (c *Client) doMultipart(ctx context.Context, method, requestURL string, fields map[string]string, fileField, fileName string, file io.Reader, v interface{}) error {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for k, val := range fields {
+		if err := writer.WriteField(k, val); err != nil {
+			return err
+		}
+	}
+	part, err := writer.CreateFormFile(fileField, fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.httpClientOrDefault().Do(req)
+	if err != nil {
+		return err
+	}
+	return handleResponse(resp, v)
+}
@@ -1,15 +1,17 @@
 package challonge
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -29,6 +31,9 @@ type Client struct {
 	key     string
 	version string
 	user    string
+
+	httpClient *http.Client
+	limiter    *rate.Limiter
 }
 
 type APIResponse struct {
@@ -57,11 +62,24 @@ type Tournament struct {
 
 	SubURL string `json:"sub_url"`
 
+	RankedBy           string  `json:"ranked_by"`
+	PointsForMatchWin  float64 `json:"pts_for_match_win"`
+	PointsForMatchTie  float64 `json:"pts_for_match_tie"`
+	PointsForGameWin   float64 `json:"pts_for_game_win"`
+	PointsForGameTie   float64 `json:"pts_for_game_tie"`
+	PointsForBye       float64 `json:"pts_for_bye"`
+	RRPointsForGameTie float64 `json:"rr_pts_for_game_tie"`
+
 	ParticipantItems []*ParticipantItem `json:"participants,omitempty"`
 	MatchItems       []*MatchItem       `json:"matches,omitempty"`
 
 	Participants []*Participant `json:"resolved_participants"`
 	Matches      []*Match       `json:"resolved_matches"`
+
+	client *Client
+
+	participantsByMisc          map[string]*Participant
+	participantsByGroupPlayerID map[int]*Participant
 }
 
 type Participant struct {
@@ -74,6 +92,8 @@ type Participant struct {
 	TotalScore     int
 	FinalRank      int   `json:"final_rank"`
 	GroupPlayerIds []int `json:"group_player_ids"`
+
+	tournament *Tournament
 }
 
 type Match struct {
@@ -96,7 +116,10 @@ type Match struct {
 	WinnerScore int
 	LoserScore  int
 
-	Scores string `json:"scores_csv"`
+	Scores string     `json:"scores_csv"`
+	Sets   []SetScore `json:"-"`
+
+	tournament *Tournament
 }
 
 /** items to flatten json structure */
@@ -133,7 +156,7 @@ func (c *Client) Debug() {
 
 func (c *Client) buildUrl(route string, v url.Values) string {
 	url := fmt.Sprintf("https://%s:%s@api.challonge.com/%s/%s.json", c.user, c.key, c.version, route)
-	if v != nil {
+	if len(v) > 0 {
 		url += "?" + v.Encode()
 	}
 
@@ -148,13 +171,6 @@ func params(p map[string]string) *url.Values {
 	return &values
 }
 
-func (r *APIResponse) hasErrors() bool {
-	if debug {
-		log.Printf("response had errors: %q", r.Errors)
-	}
-	return len(r.Errors) > 0
-}
-
 func (r *APIResponse) getTournament() *Tournament {
 	return r.Tournament.resolveRelations()
 }
@@ -174,97 +190,114 @@ func (r *TournamentRequest) WithMatches() *TournamentRequest {
 }
 
 func (t *Tournament) Update() *TournamentRequest {
-	return client.NewTournamentRequest(t.SubURL)
+	return t.clientOrDefault().NewTournamentRequest(t.SubURL)
+}
+
+// clientOrDefault returns the Client that created or fetched t. Tournaments
+// built outside of a Client call (e.g. by hand in tests) fall back to the
+// package-level client set by the most recent call to New, preserving the
+// pre-Context calling convention.
+func (t *Tournament) clientOrDefault() *Client {
+	if t.client != nil {
+		return t.client
+	}
+	return client
+}
+
+// requireTournament returns m.tournament, or an error if m was built by hand
+// (e.g. from a webhook or event ID) rather than obtained via a Tournament's
+// matches, and so never had it set.
+func (m *Match) requireTournament() (*Tournament, error) {
+	if m.tournament == nil {
+		return nil, fmt.Errorf("match %d has no associated tournament; obtain it via Tournament.GetMatch or call Match.ResolveParticipants", m.ID)
+	}
+	return m.tournament, nil
+}
+
+// requireTournament returns p.tournament, or an error if p was built by hand
+// rather than obtained via a Tournament's participants, and so never had it
+// set.
+func (p *Participant) requireTournament() (*Tournament, error) {
+	if p.tournament == nil {
+		return nil, fmt.Errorf("participant %d has no associated tournament; obtain it via Tournament.GetParticipant", p.ID)
+	}
+	return p.tournament, nil
 }
 
 func (r *TournamentRequest) Get() (*Tournament, error) {
+	return r.GetContext(context.Background())
+}
+
+func (r *TournamentRequest) GetContext(ctx context.Context) (*Tournament, error) {
 	url := r.client.buildUrl("tournaments/"+r.ID, *params(r.Params))
 	response := &APIResponse{}
-	doGet(url, response)
-	if len(response.Errors) > 0 {
-		return nil, fmt.Errorf("unable to retrieve tournament: %q", response.Errors[0])
+	if err := r.client.doGet(ctx, url, response); err != nil {
+		return nil, fmt.Errorf("unable to retrieve tournament: %w", err)
 	}
 	if response.Tournament.State != "complete" {
 		return nil, fmt.Errorf("tournament state is not 'completed'")
 	}
 	tournament := response.getTournament()
+	tournament.client = r.client
 	tournament.SubURL = r.ID
 	return tournament, nil
 }
 
-/** creates a new tournament */
-func (c *Client) CreateTournament(name string, subUrl string, domain string, open bool, tType string) (*Tournament, error) {
-	v := *params(map[string]string{
-		"tournament[name]":        name,
-		"tournament[url]":         subUrl,
-		"tournament[open_signup]": "false",
-		"tournament[subdomain]":   domain,
-	})
-	if tType == "" || tType == "single" {
-		v.Add("tournament[tournament_type]", "single elimination")
-	} else if tType == "double" {
-		v.Add("tournament[tournament_type]", "double elimination")
-	}
-	url := c.buildUrl("tournaments", v)
-	response := &APIResponse{}
-	doPost(url, response)
-	if response.hasErrors() {
-		return nil, fmt.Errorf("unable to create tournament: %q", response.Errors[0])
-	}
-	return response.getTournament(), nil
+func (t *Tournament) Start() error {
+	return t.StartContext(context.Background())
 }
 
-func (t *Tournament) Start() error {
-	v := *params(map[string]string{
-		"include_participants": "1",
-		"include_matches":      "1",
-	})
-	url := client.buildUrl("tournaments/"+t.GetUrl()+"/start", v)
-	response := &APIResponse{}
-	doPost(url, response)
-	if response.hasErrors() {
-		return fmt.Errorf("error starting tournament:  %q", response.Errors[0])
+func (t *Tournament) StartContext(ctx context.Context) error {
+	if err := t.transition(ctx, "start", "underway"); err != nil {
+		return err
 	}
-	tournament := response.getTournament()
-	if tournament.State == "underway" {
-		if debug {
-			log.Printf("tournament %q started", tournament.Name)
-		}
-	} else {
-		return fmt.Errorf("tournament has state %q, probably not started", tournament.State)
+	if debug {
+		log.Printf("tournament %q started", t.Name)
 	}
-	t = tournament
 	return nil
 }
 
 func (t *Tournament) SubmitMatch(m *Match) (*Match, error) {
+	return t.SubmitMatchContext(context.Background(), m)
+}
+
+func (t *Tournament) SubmitMatchContext(ctx context.Context, m *Match) (*Match, error) {
+	scoresCSV := setScoresCSV(m.Sets)
+	if scoresCSV == "" {
+		scoresCSV = fmt.Sprintf("%d-%d", m.PlayerOneScore, m.PlayerTwoScore)
+	}
 	v := *params(map[string]string{
-		"match[scores_csv]": fmt.Sprintf("%d-%d", m.PlayerOneScore, m.PlayerTwoScore),
+		"match[scores_csv]": scoresCSV,
 		"match[winner_id]":  fmt.Sprintf("%d", m.WinnerID),
 	})
-	url := client.buildUrl(fmt.Sprintf("tournaments/%s/matches/%d", t.GetUrl(), m.ID), v)
+	c := t.clientOrDefault()
+	url := c.buildUrl(fmt.Sprintf("tournaments/%s/matches/%d", t.GetUrl(), m.ID), v)
 	response := &APIResponse{}
-	doPut(url, response)
-	if len(response.Errors) > 0 {
-		return nil, fmt.Errorf("%q", response.Errors[0])
+	if err := c.doPut(ctx, url, response); err != nil {
+		return nil, err
 	}
-	m = &response.Match
+	response.Match.tournament = t
 	return &response.Match, nil
 }
 
 /** adds participant to tournament */
 func (t *Tournament) AddParticipant(name string, misc string) (*Participant, error) {
+	return t.AddParticipantContext(context.Background(), name, misc)
+}
+
+func (t *Tournament) AddParticipantContext(ctx context.Context, name string, misc string) (*Participant, error) {
 	v := *params(map[string]string{
 		"participant[name]": name,
 		"participant[misc]": misc,
 	})
-	url := client.buildUrl("tournaments/"+t.GetUrl()+"/participants", v)
+	c := t.clientOrDefault()
+	url := c.buildUrl("tournaments/"+t.GetUrl()+"/participants", v)
 	response := &APIResponse{}
-	doPost(url, response)
-	if len(response.Errors) > 0 {
-		return nil, fmt.Errorf("unable to add participant: %q", response.Errors[0])
+	if err := c.doPost(ctx, url, response); err != nil {
+		return nil, fmt.Errorf("unable to add participant: %w", err)
 	}
 	t.Participants = append(t.Participants, response.Participant)
+	t.reindexParticipants()
 	return response.Participant, nil
 }
 
@@ -278,20 +311,28 @@ func (t *Tournament) GetUrl() string {
 
 /** removes participant from tournament */
 func (t *Tournament) RemoveParticipant(name string) error {
+	return t.RemoveParticipantContext(context.Background(), name)
+}
+
+func (t *Tournament) RemoveParticipantContext(ctx context.Context, name string) error {
 	p := t.GetParticipantByName(name)
 	if p == nil || p.ID == 0 {
 		return fmt.Errorf("participant with name %q not found in tournament", name)
 	}
-	return t.RemoveParticipantById(p.ID)
+	return t.RemoveParticipantByIdContext(ctx, p.ID)
 }
 
 /** removes participant by id */
 func (t *Tournament) RemoveParticipantById(id int) error {
-	url := client.buildUrl("tournaments/"+t.GetUrl()+"/participants/"+strconv.Itoa(id), nil)
+	return t.RemoveParticipantByIdContext(context.Background(), id)
+}
+
+func (t *Tournament) RemoveParticipantByIdContext(ctx context.Context, id int) error {
+	c := t.clientOrDefault()
+	url := c.buildUrl("tournaments/"+t.GetUrl()+"/participants/"+strconv.Itoa(id), nil)
 	response := &APIResponse{}
-	doDelete(url, response)
-	if len(response.Errors) > 0 {
-		return fmt.Errorf("unable to delete participant: %q", response.Errors[0])
+	if err := c.doDelete(ctx, url, response); err != nil {
+		return fmt.Errorf("unable to delete participant: %w", err)
 	}
 	return nil
 }
@@ -306,10 +347,53 @@ func (t *Tournament) GetParticipantByName(name string) *Participant {
 	return t.getParticipantByCmp(func(p *Participant) bool { return p.Name == name })
 }
 func (t *Tournament) GetParticipantByMisc(misc string) *Participant {
+	if t.participantsByMisc != nil {
+		return t.participantsByMisc[misc]
+	}
 	return t.getParticipantByCmp(func(p *Participant) bool { return p.Misc == misc })
 }
 func (t *Tournament) getParticipantByGroupPlayerId(id int) *Participant {
-	return t.getParticipantByCmp(func(p *Participant) bool { return p.GroupPlayerIds[0] == id })
+	if t.participantsByGroupPlayerID != nil {
+		return t.participantsByGroupPlayerID[id]
+	}
+	return t.getParticipantByCmp(func(p *Participant) bool {
+		for _, gid := range p.GroupPlayerIds {
+			if gid == id {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// reindexParticipants rebuilds the by-misc and by-group-player-id lookup
+// maps from the current Participants slice. Call it whenever Participants
+// is replaced or appended to.
+func (t *Tournament) reindexParticipants() {
+	byMisc := make(map[string]*Participant, len(t.Participants))
+	byGroupID := make(map[int]*Participant, len(t.Participants))
+	for _, p := range t.Participants {
+		p.tournament = t
+		if p.Misc != "" {
+			byMisc[p.Misc] = p
+		}
+		for _, id := range p.GroupPlayerIds {
+			byGroupID[id] = p
+		}
+	}
+	t.participantsByMisc = byMisc
+	t.participantsByGroupPlayerID = byGroupID
+}
+
+// resetParticipantTotals zeroes the cumulative Wins/Losses/TotalScore on
+// every participant so a fresh pass over t.Matches recomputes them instead
+// of adding on top of a previous pass's totals.
+func (t *Tournament) resetParticipantTotals() {
+	for _, p := range t.Participants {
+		p.Wins = 0
+		p.Losses = 0
+		p.TotalScore = 0
+	}
 }
 
 func (t *Tournament) getParticipantByCmp(cmp cmp) *Participant {
@@ -335,6 +419,7 @@ func (t *Tournament) GetOpenMatches() []*Match {
 func (t *Tournament) getMatches(state string) []*Match {
 	matches := make([]*Match, 0)
 
+	t.resetParticipantTotals()
 	for _, m := range t.Matches {
 		m.ResolveParticipants(t)
 		if state == STATE_ALL {
@@ -397,6 +482,7 @@ func separateScores(score string) (int, int, error) {
 }
 
 func (m *Match) ResolveParticipants(t *Tournament) {
+	m.tournament = t
 	m.PlayerOne = t.GetParticipant(m.PlayerOneID)
 	m.PlayerTwo = t.GetParticipant(m.PlayerTwoID)
 
@@ -408,15 +494,24 @@ func (m *Match) ResolveParticipants(t *Tournament) {
 		m.PlayerTwo = t.getParticipantByGroupPlayerId(m.PlayerTwoID)
 	}
 
-	scoreOne, scoreTwo, err := separateScores(m.Scores)
-
+	sets, err := parseScoresCSV(m.Scores)
 	if err != nil {
-		m.PlayerOneScore = 0
-		m.PlayerTwoScore = 0
+		sets = nil
 	}
+	m.Sets = sets
 
-	m.PlayerOneScore = scoreOne
-	m.PlayerTwoScore = scoreTwo
+	m.PlayerOneScore = 0
+	m.PlayerTwoScore = 0
+	for _, s := range sets {
+		m.PlayerOneScore += s.PlayerOne
+		m.PlayerTwoScore += s.PlayerTwo
+	}
+
+	if m.PlayerOne == nil || m.PlayerTwo == nil {
+		// Bye match: only one side has a real participant, so there's no
+		// opponent to tally a win/loss/score against.
+		return
+	}
 
 	m.PlayerOne.TotalScore += m.PlayerOneScore
 	m.PlayerTwo.TotalScore += m.PlayerTwoScore
@@ -446,6 +541,7 @@ func (t *Tournament) resolveRelations() *Tournament {
 	}
 	t.Participants = participants
 	t.ParticipantItems = nil
+	t.reindexParticipants()
 
 	matches := make([]*Match, 0)
 	for _, item := range t.MatchItems {
@@ -477,71 +573,6 @@ func DiffMatches(matches1 []*Match, matches2 []*Match) []*Match {
 	return diff
 }
 
-func doGet(url string, v *APIResponse) {
-	if debug {
-		log.Print("gets resource on url ", url)
-	}
-	resp, err := http.Get(url)
-	if debug {
-		log.Print("got headers ", resp)
-	}
-	if err != nil {
-		log.Fatal("unable to get resource ", err)
-	}
-	handleResponse(resp, v)
-}
-
-func doPost(url string, v interface{}) {
-	if debug {
-		log.Print("posts resource on url ", url)
-	}
-	resp, err := http.Post(url, "application/json", nil)
-	if err != nil {
-		log.Fatal("unable to get resource ", err)
-	}
-	handleResponse(resp, v)
-}
-
-func doPut(url string, v interface{}) {
-	req, err := http.NewRequest("PUT", url, nil)
-	log.Print("puts resource on url ", url)
-	if err != nil {
-		log.Fatal("unable to create put request")
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Fatal("unable to delete", err)
-	}
-	handleResponse(resp, v)
-}
-
-func doDelete(url string, v interface{}) {
-	req, err := http.NewRequest("DELETE", url, nil)
-	log.Print("deletes resource on url ", url)
-	if err != nil {
-		log.Fatal("unable to create delete request")
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Fatal("unable to delete", err)
-	}
-	handleResponse(resp, v)
-}
-
-func handleResponse(r *http.Response, v interface{}) {
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		log.Fatal("unable to read response", err)
-	}
-	err = json.Unmarshal(body, v)
-	if err != nil {
-		log.Print("Error unmarshaling json ", err)
-	}
-	if debug {
-		log.Print("unmarshaled to ", v)
-	}
-}
-
 func (t *Tournament) UnmarshalJSON(b []byte) (err error) {
 	placeholder := tournament{}
 	if err = json.Unmarshal(b, &placeholder); err == nil {
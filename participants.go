@@ -0,0 +1,150 @@
+package challonge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ParticipantInput describes one roster entry for BulkAddParticipants.
+type ParticipantInput struct {
+	Name              string
+	Seed              int
+	Misc              string
+	Email             string
+	InviteNameOrEmail string
+}
+
+func (p ParticipantInput) values(index int) url.Values {
+	prefix := fmt.Sprintf("participants[%d]", index)
+	v := url.Values{}
+	if p.Name != "" {
+		v.Set(prefix+"[name]", p.Name)
+	}
+	if p.Seed != 0 {
+		v.Set(prefix+"[seed]", strconv.Itoa(p.Seed))
+	}
+	if p.Misc != "" {
+		v.Set(prefix+"[misc]", p.Misc)
+	}
+	if p.Email != "" {
+		v.Set(prefix+"[email]", p.Email)
+	}
+	if p.InviteNameOrEmail != "" {
+		v.Set(prefix+"[invite_name_or_email]", p.InviteNameOrEmail)
+	}
+	return v
+}
+
+// BulkAddParticipants imports an entire roster in a single request instead
+// of one AddParticipant round-trip per player.
+func (t *Tournament) BulkAddParticipants(ctx context.Context, players []ParticipantInput) ([]*Participant, error) {
+	c := t.clientOrDefault()
+	v := url.Values{}
+	for i, p := range players {
+		for k, vals := range p.values(i) {
+			v[k] = vals
+		}
+	}
+	reqURL := c.buildUrl("tournaments/"+t.GetUrl()+"/participants/bulk_add", v)
+	var items []*ParticipantItem
+	if err := c.doPost(ctx, reqURL, &items); err != nil {
+		return nil, fmt.Errorf("unable to bulk add participants: %w", err)
+	}
+
+	added := make([]*Participant, 0, len(items))
+	for _, item := range items {
+		p := &item.Participant
+		t.Participants = append(t.Participants, p)
+		added = append(added, p)
+	}
+	t.reindexParticipants()
+	return added, nil
+}
+
+// ClearParticipants removes every participant from the tournament. Only
+// valid before the tournament has started.
+func (t *Tournament) ClearParticipants(ctx context.Context) error {
+	c := t.clientOrDefault()
+	reqURL := c.buildUrl("tournaments/"+t.GetUrl()+"/participants/clear", nil)
+	response := &APIResponse{}
+	if err := c.doDelete(ctx, reqURL, response); err != nil {
+		return fmt.Errorf("unable to clear participants: %w", err)
+	}
+	t.Participants = nil
+	t.reindexParticipants()
+	return nil
+}
+
+// RandomizeParticipants randomizes the seeding of the tournament's
+// participants. It is the same operation as Tournament.Randomize.
+func (t *Tournament) RandomizeParticipants(ctx context.Context) error {
+	return t.RandomizeContext(ctx)
+}
+
+// ParticipantPatch carries the subset of participant fields a caller wants
+// to change. A nil field is left untouched by Participant.Update.
+type ParticipantPatch struct {
+	Name *string
+	Seed *int
+	Misc *string
+}
+
+func (p ParticipantPatch) values() url.Values {
+	v := url.Values{}
+	if p.Name != nil {
+		v.Set("participant[name]", *p.Name)
+	}
+	if p.Seed != nil {
+		v.Set("participant[seed]", strconv.Itoa(*p.Seed))
+	}
+	if p.Misc != nil {
+		v.Set("participant[misc]", *p.Misc)
+	}
+	return v
+}
+
+// Update edits a participant's seed/name/misc after registration.
+func (p *Participant) Update(ctx context.Context, patch ParticipantPatch) error {
+	t, err := p.requireTournament()
+	if err != nil {
+		return err
+	}
+	c := t.clientOrDefault()
+	route := fmt.Sprintf("tournaments/%s/participants/%d", t.GetUrl(), p.ID)
+	response := &APIResponse{}
+	if err := c.doPut(ctx, c.buildUrl(route, patch.values()), response); err != nil {
+		return fmt.Errorf("unable to update participant: %w", err)
+	}
+	*p = *response.Participant
+	p.tournament = t
+	t.reindexParticipants()
+	return nil
+}
+
+// CheckIn marks the participant as checked in.
+func (p *Participant) CheckIn(ctx context.Context) error {
+	return p.transition(ctx, "check_in")
+}
+
+// UndoCheckIn reverts a previous CheckIn.
+func (p *Participant) UndoCheckIn(ctx context.Context) error {
+	return p.transition(ctx, "undo_check_in")
+}
+
+func (p *Participant) transition(ctx context.Context, action string) error {
+	t, err := p.requireTournament()
+	if err != nil {
+		return err
+	}
+	c := t.clientOrDefault()
+	route := fmt.Sprintf("tournaments/%s/participants/%d/%s", t.GetUrl(), p.ID, action)
+	response := &APIResponse{}
+	if err := c.doPost(ctx, c.buildUrl(route, nil), response); err != nil {
+		return fmt.Errorf("unable to %s participant: %w", action, err)
+	}
+	*p = *response.Participant
+	p.tournament = t
+	return nil
+}
@@ -0,0 +1,194 @@
+package challonge
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Award categories recognized by the standings subsystem.
+const (
+	AwardMatchWin = "match_win"
+	AwardMatchTie = "match_tie"
+	AwardGameWin  = "game_win"
+	AwardGameTie  = "game_tie"
+	AwardBye      = "bye"
+)
+
+// Award records a single point-scoring event for a participant, mirroring
+// one line of Challonge's Swiss/round-robin points log.
+type Award struct {
+	When          time.Time
+	ParticipantID int
+	Category      string
+	Points        float64
+	MatchID       int
+}
+
+// MarshalJSON encodes an Award as a compact positional array
+// [when, participant_id, category, points] instead of a JSON object, so a
+// long log streams cheaply.
+func (a *Award) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{
+		a.When.Format(time.RFC3339),
+		a.ParticipantID,
+		a.Category,
+		a.Points,
+	})
+}
+
+// AwardList is a sortable list of Awards, ordered by When and then by
+// ParticipantID.
+type AwardList []*Award
+
+func (a AwardList) Len() int      { return len(a) }
+func (a AwardList) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a AwardList) Less(i, j int) bool {
+	if !a[i].When.Equal(a[j].When) {
+		return a[i].When.Before(a[j].When)
+	}
+	return a[i].ParticipantID < a[j].ParticipantID
+}
+
+// MarshalJSON encodes the list as an array of Award's compact positional
+// arrays.
+func (a AwardList) MarshalJSON() ([]byte, error) {
+	out := make([]json.RawMessage, len(a))
+	for i, award := range a {
+		b, err := award.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return json.Marshal(out)
+}
+
+// Standing is a participant's computed rank within a tournament, along with
+// the log of point-scoring events that produced it.
+type Standing struct {
+	Participant *Participant
+
+	Rank   int
+	Wins   int
+	Losses int
+
+	MatchDifferential int
+	GameDifferential  int
+	TotalScore        float64
+
+	PointsLog AwardList
+}
+
+// Standings computes the current ranking of every participant in t from its
+// resolved matches, using the points-per-category settings Challonge
+// returned on the tournament (pts_for_match_win, pts_for_bye, ...). It does
+// not make any API calls.
+func (t *Tournament) Standings() []*Standing {
+	wins := make(map[int]int)
+	losses := make(map[int]int)
+	matchDiff := make(map[int]int)
+	gameDiff := make(map[int]int)
+	points := make(map[int]float64)
+	logs := make(map[int]AwardList)
+
+	award := func(pid int, category string, pts float64, matchID int, when time.Time) {
+		points[pid] += pts
+		logs[pid] = append(logs[pid], &Award{
+			When:          when,
+			ParticipantID: pid,
+			Category:      category,
+			Points:        pts,
+			MatchID:       matchID,
+		})
+	}
+
+	// rr_pts_for_game_tie overrides pts_for_game_tie for tied games in
+	// round-robin tournaments; everything else uses pts_for_game_tie.
+	gameTiePoints := t.PointsForGameTie
+	if t.Type == string(RoundRobin) {
+		gameTiePoints = t.RRPointsForGameTie
+	}
+
+	for _, m := range t.GetMatches() {
+		if m.PlayerOne == nil || m.PlayerTwo == nil {
+			// ResolveParticipants leaves Winner/Loser unset on a bye, so the
+			// bye goes to the side that is present and reported as winner.
+			if m.PlayerOne != nil && m.WinnerID == m.PlayerOneID {
+				award(m.PlayerOne.ID, AwardBye, t.PointsForBye, m.ID, m.UpdatedAt)
+			} else if m.PlayerTwo != nil && m.WinnerID == m.PlayerTwoID {
+				award(m.PlayerTwo.ID, AwardBye, t.PointsForBye, m.ID, m.UpdatedAt)
+			}
+			continue
+		}
+		if m.Winner == nil || m.Loser == nil {
+			continue
+		}
+		wins[m.Winner.ID]++
+		losses[m.Loser.ID]++
+		matchDiff[m.Winner.ID]++
+		matchDiff[m.Loser.ID]--
+		gameDiff[m.Winner.ID] += m.WinnerScore - m.LoserScore
+		gameDiff[m.Loser.ID] += m.LoserScore - m.WinnerScore
+
+		award(m.Winner.ID, AwardMatchWin, t.PointsForMatchWin, m.ID, m.UpdatedAt)
+		if m.WinnerScore == m.LoserScore {
+			award(m.Winner.ID, AwardMatchTie, t.PointsForMatchTie, m.ID, m.UpdatedAt)
+			award(m.Loser.ID, AwardMatchTie, t.PointsForMatchTie, m.ID, m.UpdatedAt)
+		}
+
+		for _, s := range m.Sets {
+			switch {
+			case s.PlayerOne > s.PlayerTwo:
+				award(m.PlayerOne.ID, AwardGameWin, t.PointsForGameWin, m.ID, m.UpdatedAt)
+			case s.PlayerTwo > s.PlayerOne:
+				award(m.PlayerTwo.ID, AwardGameWin, t.PointsForGameWin, m.ID, m.UpdatedAt)
+			default:
+				award(m.PlayerOne.ID, AwardGameTie, gameTiePoints, m.ID, m.UpdatedAt)
+				award(m.PlayerTwo.ID, AwardGameTie, gameTiePoints, m.ID, m.UpdatedAt)
+			}
+		}
+	}
+
+	standings := make([]*Standing, 0, len(t.Participants))
+	for _, p := range t.Participants {
+		pointsLog := logs[p.ID]
+		sort.Sort(pointsLog)
+		standings = append(standings, &Standing{
+			Participant:       p,
+			Wins:              wins[p.ID],
+			Losses:            losses[p.ID],
+			MatchDifferential: matchDiff[p.ID],
+			GameDifferential:  gameDiff[p.ID],
+			TotalScore:        points[p.ID],
+			PointsLog:         pointsLog,
+		})
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].TotalScore > standings[j].TotalScore
+	})
+	for i, s := range standings {
+		s.Rank = i + 1
+	}
+	return standings
+}
+
+// DiffStandings returns the Standings in t's current state whose rank moved
+// relative to prev, so bots can announce rank changes without re-rendering
+// the whole table.
+func (t *Tournament) DiffStandings(prev []*Standing) []*Standing {
+	prevRank := make(map[int]int, len(prev))
+	for _, s := range prev {
+		prevRank[s.Participant.ID] = s.Rank
+	}
+
+	current := t.Standings()
+	changed := make([]*Standing, 0)
+	for _, s := range current {
+		if oldRank, ok := prevRank[s.Participant.ID]; !ok || oldRank != s.Rank {
+			changed = append(changed, s)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,322 @@
+package challonge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TournamentType is one of the bracket formats Challonge supports.
+type TournamentType string
+
+const (
+	SingleElimination TournamentType = "single elimination"
+	DoubleElimination TournamentType = "double elimination"
+	RoundRobin        TournamentType = "round robin"
+	Swiss             TournamentType = "swiss"
+	FreeForAll        TournamentType = "free for all"
+)
+
+// TournamentOptions describes the fields accepted when creating a
+// tournament. Name, URL and OpenSignup are always sent; other zero-valued
+// fields are left off the request so Challonge's own defaults apply; Type
+// defaults to SingleElimination.
+type TournamentOptions struct {
+	Name        string
+	URL         string
+	SubDomain   string
+	Description string
+	Type        TournamentType
+
+	OpenSignup bool
+
+	SignupCap       int
+	CheckInDuration int // minutes
+	RankedBy        string
+
+	SequentialPairings  bool
+	HoldThirdPlaceMatch bool
+
+	PointsForMatchWin  float64
+	PointsForMatchTie  float64
+	PointsForGameWin   float64
+	PointsForGameTie   float64
+	PointsForBye       float64
+	RRPointsForGameTie float64
+}
+
+func (o TournamentOptions) values() url.Values {
+	v := url.Values{}
+	v.Set("tournament[name]", o.Name)
+	v.Set("tournament[url]", o.URL)
+	v.Set("tournament[open_signup]", strconv.FormatBool(o.OpenSignup))
+
+	tType := o.Type
+	if tType == "" {
+		tType = SingleElimination
+	}
+	v.Set("tournament[tournament_type]", string(tType))
+
+	if o.SubDomain != "" {
+		v.Set("tournament[subdomain]", o.SubDomain)
+	}
+	if o.Description != "" {
+		v.Set("tournament[description]", o.Description)
+	}
+	if o.SignupCap > 0 {
+		v.Set("tournament[signup_cap]", strconv.Itoa(o.SignupCap))
+	}
+	if o.CheckInDuration > 0 {
+		v.Set("tournament[check_in_duration]", strconv.Itoa(o.CheckInDuration))
+	}
+	if o.RankedBy != "" {
+		v.Set("tournament[ranked_by]", o.RankedBy)
+	}
+	if o.SequentialPairings {
+		v.Set("tournament[sequential_pairings]", "true")
+	}
+	if o.HoldThirdPlaceMatch {
+		v.Set("tournament[hold_third_place_match]", "true")
+	}
+	if o.PointsForMatchWin != 0 {
+		v.Set("tournament[pts_for_match_win]", strconv.FormatFloat(o.PointsForMatchWin, 'f', -1, 64))
+	}
+	if o.PointsForMatchTie != 0 {
+		v.Set("tournament[pts_for_match_tie]", strconv.FormatFloat(o.PointsForMatchTie, 'f', -1, 64))
+	}
+	if o.PointsForGameWin != 0 {
+		v.Set("tournament[pts_for_game_win]", strconv.FormatFloat(o.PointsForGameWin, 'f', -1, 64))
+	}
+	if o.PointsForGameTie != 0 {
+		v.Set("tournament[pts_for_game_tie]", strconv.FormatFloat(o.PointsForGameTie, 'f', -1, 64))
+	}
+	if o.PointsForBye != 0 {
+		v.Set("tournament[pts_for_bye]", strconv.FormatFloat(o.PointsForBye, 'f', -1, 64))
+	}
+	if o.RRPointsForGameTie != 0 {
+		v.Set("tournament[rr_pts_for_game_tie]", strconv.FormatFloat(o.RRPointsForGameTie, 'f', -1, 64))
+	}
+	return v
+}
+
+/** creates a new tournament */
+func (c *Client) CreateTournament(opts TournamentOptions) (*Tournament, error) {
+	return c.CreateTournamentContext(context.Background(), opts)
+}
+
+func (c *Client) CreateTournamentContext(ctx context.Context, opts TournamentOptions) (*Tournament, error) {
+	url := c.buildUrl("tournaments", opts.values())
+	response := &APIResponse{}
+	if err := c.doPost(ctx, url, response); err != nil {
+		return nil, fmt.Errorf("unable to create tournament: %w", err)
+	}
+	tournament := response.getTournament()
+	tournament.client = c
+	return tournament, nil
+}
+
+// TournamentFilter narrows the results of ListTournaments. Zero-valued
+// fields are omitted from the request.
+type TournamentFilter struct {
+	State         string
+	Type          TournamentType
+	SubDomain     string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Page          int
+}
+
+func (f TournamentFilter) values() url.Values {
+	v := url.Values{}
+	if f.State != "" {
+		v.Set("state", f.State)
+	}
+	if f.Type != "" {
+		v.Set("type", string(f.Type))
+	}
+	if f.SubDomain != "" {
+		v.Set("subdomain", f.SubDomain)
+	}
+	if !f.CreatedAfter.IsZero() {
+		v.Set("created_after", f.CreatedAfter.Format("2006-01-02"))
+	}
+	if !f.CreatedBefore.IsZero() {
+		v.Set("created_before", f.CreatedBefore.Format("2006-01-02"))
+	}
+	if f.Page > 0 {
+		v.Set("page", strconv.Itoa(f.Page))
+	}
+	return v
+}
+
+// ListTournaments returns the tournaments owned by the account behind c,
+// narrowed by filter.
+func (c *Client) ListTournaments(filter TournamentFilter) ([]*Tournament, error) {
+	return c.ListTournamentsContext(context.Background(), filter)
+}
+
+func (c *Client) ListTournamentsContext(ctx context.Context, filter TournamentFilter) ([]*Tournament, error) {
+	url := c.buildUrl("tournaments", filter.values())
+	var items []*TournamentItem
+	if err := c.doGet(ctx, url, &items); err != nil {
+		return nil, fmt.Errorf("unable to list tournaments: %w", err)
+	}
+	tournaments := make([]*Tournament, 0, len(items))
+	for _, item := range items {
+		t := item.Tournament
+		t.client = c
+		tournaments = append(tournaments, &t)
+	}
+	return tournaments, nil
+}
+
+// TournamentPatch carries the subset of TournamentOptions fields a caller
+// wants to change. A nil field is left untouched by UpdateTournament.
+type TournamentPatch struct {
+	Name                *string
+	Description         *string
+	RankedBy            *string
+	SignupCap           *int
+	CheckInDuration     *int
+	SequentialPairings  *bool
+	HoldThirdPlaceMatch *bool
+	OpenSignup          *bool
+}
+
+func (p TournamentPatch) values() url.Values {
+	v := url.Values{}
+	if p.Name != nil {
+		v.Set("tournament[name]", *p.Name)
+	}
+	if p.Description != nil {
+		v.Set("tournament[description]", *p.Description)
+	}
+	if p.RankedBy != nil {
+		v.Set("tournament[ranked_by]", *p.RankedBy)
+	}
+	if p.SignupCap != nil {
+		v.Set("tournament[signup_cap]", strconv.Itoa(*p.SignupCap))
+	}
+	if p.CheckInDuration != nil {
+		v.Set("tournament[check_in_duration]", strconv.Itoa(*p.CheckInDuration))
+	}
+	if p.SequentialPairings != nil {
+		v.Set("tournament[sequential_pairings]", strconv.FormatBool(*p.SequentialPairings))
+	}
+	if p.HoldThirdPlaceMatch != nil {
+		v.Set("tournament[hold_third_place_match]", strconv.FormatBool(*p.HoldThirdPlaceMatch))
+	}
+	if p.OpenSignup != nil {
+		v.Set("tournament[open_signup]", strconv.FormatBool(*p.OpenSignup))
+	}
+	return v
+}
+
+// UpdateTournament applies patch to t in place and returns t, matching the
+// other lifecycle verbs (Finalize, Reset, Randomize, ProcessCheckIns) that
+// mutate their receiver rather than leaving the caller holding stale data.
+func (c *Client) UpdateTournament(t *Tournament, patch TournamentPatch) (*Tournament, error) {
+	return c.UpdateTournamentContext(context.Background(), t, patch)
+}
+
+func (c *Client) UpdateTournamentContext(ctx context.Context, t *Tournament, patch TournamentPatch) (*Tournament, error) {
+	v := patch.values()
+	v.Set("include_participants", "1")
+	v.Set("include_matches", "1")
+	url := c.buildUrl("tournaments/"+t.GetUrl(), v)
+	response := &APIResponse{}
+	if err := c.doPut(ctx, url, response); err != nil {
+		return nil, fmt.Errorf("unable to update tournament: %w", err)
+	}
+	tournament := response.getTournament()
+	*t = *tournament
+	t.client = c
+	t.reindexParticipants()
+	for _, m := range t.Matches {
+		m.tournament = t
+	}
+	return t, nil
+}
+
+// DeleteTournament permanently deletes t. This cannot be undone.
+func (c *Client) DeleteTournament(t *Tournament) error {
+	return c.DeleteTournamentContext(context.Background(), t)
+}
+
+func (c *Client) DeleteTournamentContext(ctx context.Context, t *Tournament) error {
+	url := c.buildUrl("tournaments/"+t.GetUrl(), nil)
+	response := &APIResponse{}
+	if err := c.doDelete(ctx, url, response); err != nil {
+		return fmt.Errorf("unable to delete tournament: %w", err)
+	}
+	return nil
+}
+
+// Finalize finalizes the tournament's results after the last match has been
+// submitted, making it visible as 'complete'.
+func (t *Tournament) Finalize() error {
+	return t.FinalizeContext(context.Background())
+}
+
+func (t *Tournament) FinalizeContext(ctx context.Context) error {
+	return t.transition(ctx, "finalize", "complete")
+}
+
+// Reset reverts the tournament to its pre-start state, deleting all of its
+// matches.
+func (t *Tournament) Reset() error {
+	return t.ResetContext(context.Background())
+}
+
+func (t *Tournament) ResetContext(ctx context.Context) error {
+	return t.transition(ctx, "reset", "pending")
+}
+
+// Randomize randomizes the seeding of the tournament's participants. Only
+// valid before the tournament has started.
+func (t *Tournament) Randomize() error {
+	return t.RandomizeContext(context.Background())
+}
+
+func (t *Tournament) RandomizeContext(ctx context.Context) error {
+	return t.transition(ctx, "randomize", "")
+}
+
+// ProcessCheckIns marks participants who checked in as such and readies the
+// tournament to start, per Challonge's check-in workflow.
+func (t *Tournament) ProcessCheckIns() error {
+	return t.ProcessCheckInsContext(context.Background())
+}
+
+func (t *Tournament) ProcessCheckInsContext(ctx context.Context) error {
+	return t.transition(ctx, "process_check_ins", "")
+}
+
+// transition POSTs to one of the tournament state-change endpoints and
+// replaces t's fields with the result. If wantState is non-empty the
+// response's state is checked against it.
+func (t *Tournament) transition(ctx context.Context, action string, wantState string) error {
+	v := *params(map[string]string{
+		"include_participants": "1",
+		"include_matches":      "1",
+	})
+	c := t.clientOrDefault()
+	url := c.buildUrl("tournaments/"+t.GetUrl()+"/"+action, v)
+	response := &APIResponse{}
+	if err := c.doPost(ctx, url, response); err != nil {
+		return fmt.Errorf("unable to %s tournament: %w", action, err)
+	}
+	tournament := response.getTournament()
+	if wantState != "" && tournament.State != wantState {
+		return fmt.Errorf("tournament has state %q after %s", tournament.State, action)
+	}
+	*t = *tournament
+	t.client = c
+	t.reindexParticipants()
+	for _, m := range t.Matches {
+		m.tournament = t
+	}
+	return nil
+}
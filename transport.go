@@ -0,0 +1,191 @@
+package challonge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	maxRetries  = 5
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+)
+
+// APIError is returned whenever the Challonge API responds with a non-2xx
+// status code. StatusCode carries the HTTP status and Errors carries the
+// messages Challonge returned in the response body, if any.
+type APIError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("challonge: request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("challonge: request failed with status %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+// WithHTTPClient lets callers supply their own *http.Client, e.g. to set
+// timeouts or a custom transport for instrumentation. Returns c so it can be
+// chained off New.
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c.httpClient = hc
+	return c
+}
+
+// WithRateLimit caps outgoing requests to r requests/sec with the given
+// burst, using a token-bucket limiter. Challonge documents a default limit
+// of around 600 requests/hour.
+func (c *Client) WithRateLimit(r rate.Limit, burst int) *Client {
+	c.limiter = rate.NewLimiter(r, burst)
+	return c
+}
+
+func (c *Client) httpClientOrDefault() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) doGet(ctx context.Context, url string, v interface{}) error {
+	return c.do(ctx, http.MethodGet, url, v)
+}
+
+func (c *Client) doPost(ctx context.Context, url string, v interface{}) error {
+	return c.do(ctx, http.MethodPost, url, v)
+}
+
+func (c *Client) doPut(ctx context.Context, url string, v interface{}) error {
+	return c.do(ctx, http.MethodPut, url, v)
+}
+
+func (c *Client) doDelete(ctx context.Context, url string, v interface{}) error {
+	return c.do(ctx, http.MethodDelete, url, v)
+}
+
+// do issues a request, retrying on 429/5xx responses and transient network
+// errors with exponential backoff, honoring any Retry-After header the
+// server sends back. It blocks on the rate limiter, if one is configured,
+// before every attempt.
+func (c *Client) do(ctx context.Context, method, url string, v interface{}) error {
+	backoff := baseBackoff
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return err
+		}
+		if debug {
+			log.Printf("%s resource on url %s (attempt %d)", method, url, attempt+1)
+		}
+
+		resp, err := c.httpClientOrDefault().Do(req)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !sleep(ctx, backoff) {
+				return ctx.Err()
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		if shouldRetry(resp.StatusCode) && attempt < maxRetries {
+			wait := retryAfter(resp, backoff)
+			resp.Body.Close()
+			lastErr = &APIError{StatusCode: resp.StatusCode}
+			if !sleep(ctx, wait) {
+				return ctx.Err()
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		return handleResponse(resp, v)
+	}
+	return lastErr
+}
+
+func shouldRetry(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+func nextBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next > maxBackoff {
+		return maxBackoff
+	}
+	return next
+}
+
+// retryAfter honors the server's Retry-After header (seconds form) when
+// present, falling back to the caller's backoff otherwise.
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	return fallback
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func handleResponse(r *http.Response, v interface{}) error {
+	defer r.Body.Close()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read response: %v", err)
+	}
+	if debug {
+		log.Print("unmarshaling body ", string(body))
+	}
+
+	if r.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: r.StatusCode}
+		var wrapper struct {
+			Errors []string `json:"errors"`
+		}
+		if err := json.Unmarshal(body, &wrapper); err == nil {
+			apiErr.Errors = wrapper.Errors
+		}
+		return apiErr
+	}
+
+	if v == nil || len(body) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("unable to unmarshal response: %v", err)
+	}
+	return nil
+}